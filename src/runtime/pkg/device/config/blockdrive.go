@@ -0,0 +1,46 @@
+// Copyright (c) 2016 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package config
+
+import "github.com/sirupsen/logrus"
+
+var configLog = logrus.WithField("source", "device-config")
+
+// SetLogger sets the logger for the device/config package.
+func SetLogger(logger *logrus.Entry) {
+	fields := configLog.Data
+	configLog = logger.WithFields(fields)
+}
+
+// DeviceType describes a class of device that can be hotplugged into a
+// running sandbox.
+type DeviceType string
+
+const (
+	// DeviceBlock is a block device, surfaced to the guest as a disk.
+	DeviceBlock DeviceType = "block"
+)
+
+// BlockDrive represents a block device to be made available inside the
+// guest, either as part of the root/rootfs setup or hotplugged later.
+type BlockDrive struct {
+	// File is the path to the backing file or block device on the host.
+	File string
+
+	// Format is the backing file's format, e.g. "raw" or "qcow2".
+	Format string
+
+	// ID uniquely identifies this drive within the sandbox.
+	ID string
+
+	// Index is the drive's position on its controller.
+	Index int
+
+	// Swap marks this drive as backing guest swap space rather than a
+	// regular block device, so the agent formats and enables it with
+	// mkswap/swapon instead of mounting it.
+	Swap bool
+}
@@ -0,0 +1,59 @@
+// Copyright (c) 2016 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package virtcontainers
+
+import (
+	"context"
+	"errors"
+
+	deviceConfig "github.com/kata-containers/kata-containers/src/runtime/pkg/device/config"
+)
+
+// agent is the subset of the guest agent surface this package's swap and
+// snapshot/restore entry points call directly. The bulk of the interface
+// (container lifecycle, I/O streaming, ...) lives alongside the rest of
+// sandbox creation.
+type agent interface {
+	// addSwap asks the agent to mkswap/swapon the block device described
+	// by drive, which has already been hotplugged into the guest.
+	addSwap(ctx context.Context, drive *deviceConfig.BlockDrive) error
+
+	// removeSwap asks the agent to swapoff the block device described by
+	// drive, before it is unplugged from the guest.
+	removeSwap(ctx context.Context, drive *deviceConfig.BlockDrive) error
+
+	// pause quiesces every running container's processes so the guest is
+	// safe to snapshot: nothing is mutating memory the hypervisor is about
+	// to dump. It does not stop or delete the containers.
+	pause(ctx context.Context) error
+
+	// reconnect re-establishes the agent's RPC channel to a guest that was
+	// just restored from a hypervisor snapshot, so subsequent calls reach
+	// the same agent process rather than a fresh one.
+	reconnect(ctx context.Context) error
+
+	// containerStatus asks the agent for container id's live state, so
+	// reconcileContainers can confirm what a snapshot recorded still holds
+	// rather than trusting it blindly. It returns "" if the agent has no
+	// opinion (e.g. a mock/test agent), which callers should treat as "keep
+	// the recorded state".
+	containerStatus(ctx context.Context, id string) (string, error)
+}
+
+// ErrAgentUnreachable is returned (wrapped) by kataAgent's methods when it
+// has no gRPC client to reach the guest with. An error from a client whose
+// guest has gone away is returned as the client reported it, not as
+// ErrAgentUnreachable. It lets callers doing idempotent cleanup tell "the
+// guest-side step is moot because there is no agent to reach" apart from a
+// real failure worth retrying.
+var ErrAgentUnreachable = errors.New("agent is unreachable")
+
+// isAgentUnreachable reports whether err indicates the guest agent is no
+// longer reachable, as opposed to the requested operation having failed
+// against a live agent.
+func isAgentUnreachable(err error) bool {
+	return errors.Is(err, ErrAgentUnreachable)
+}
@@ -68,16 +68,26 @@ func CreateSandbox(ctx context.Context, sandboxConfig SandboxConfig, factory Fac
 func createSandboxFromConfig(ctx context.Context, sandboxConfig SandboxConfig, factory Factory, prestartHookFunc func(context.Context) error) (_ *Sandbox, err error) {
 	span, ctx := katatrace.Trace(ctx, virtLog, "createSandboxFromConfig", apiTracingTags)
 	defer span.End()
+
+	profile := &SandboxCreationProfile{}
+	progressFunc := sandboxConfig.ProgressFunc
+
 	start := time.Now()
 	// Create the sandbox.
 	s, err := createSandbox(ctx, sandboxConfig, factory)
+	elapsed := recordPhase(profile, progressFunc, PhaseCreateSandbox, start, err)
+	katatrace.AddTags(span, string(PhaseCreateSandbox)+"DurationMs", elapsed.Milliseconds())
 	if err != nil {
 		return nil, err
 	}
-	virtLog.Info(fmt.Sprintf("[MZH] createSandbox TIME: %v", time.Since(start)))
 	// Cleanup sandbox resources in case of any failure
 	defer func() {
 		if err != nil {
+			if s.config.EnableSwap {
+				if swapErr := s.RemoveSwap(ctx); swapErr != nil {
+					virtLog.WithError(swapErr).Warn("failed to remove swap file while rolling back sandbox creation")
+				}
+			}
 			s.Delete(ctx)
 		}
 	}()
@@ -89,47 +99,167 @@ func createSandboxFromConfig(ctx context.Context, sandboxConfig SandboxConfig, f
 			s.removeNetwork(ctx)
 		}
 	}()
-	start = time.Now()
-	// Create the sandbox network
-	if err = s.createNetwork(ctx); err != nil {
+	// rollback to stop VM if error occurs. Registered before startVM runs
+	// (rather than just after, as the sequential path used to) because with
+	// ParallelStartup the VM can come up successfully while createNetwork or
+	// setupResourceController still fails, and it must still be torn down.
+	defer func() {
+		if err != nil {
+			s.stopVM(ctx)
+		}
+	}()
+
+	// createNetwork and setupResourceController don't depend on each other
+	// or on the VM, and startVM doesn't depend on either of them, so with
+	// ParallelStartup the three run concurrently; otherwise they run in
+	// their historical sequential order.
+	results, err := runNetworkCgroupsAndVM(ctx, s, prestartHookFunc, sandboxConfig.ParallelStartup)
+	for _, r := range results {
+		if r.phase == "" {
+			continue
+		}
+		elapsed := recordPhaseElapsed(profile, progressFunc, r.phase, r.elapsed, r.err)
+		katatrace.AddTags(span, string(r.phase)+"DurationMs", elapsed.Milliseconds())
+	}
+	if err != nil {
 		return nil, err
 	}
-	virtLog.Info(fmt.Sprintf("[MZH] createNetwork TIME: %v", time.Since(start)))
 	start = time.Now()
-	// Set the sandbox host cgroups.
-	if err := s.setupResourceController(); err != nil {
+	s.postCreatedNetwork(ctx)
+	elapsed = recordPhase(profile, progressFunc, PhasePostCreatedNetwork, start, nil)
+	katatrace.AddTags(span, string(PhasePostCreatedNetwork)+"DurationMs", elapsed.Milliseconds())
+	start = time.Now()
+	err = s.getAndStoreGuestDetails(ctx)
+	elapsed = recordPhase(profile, progressFunc, PhaseGetAndStoreGuestDetails, start, err)
+	katatrace.AddTags(span, string(PhaseGetAndStoreGuestDetails)+"DurationMs", elapsed.Milliseconds())
+	if err != nil {
 		return nil, err
 	}
-	virtLog.Info(fmt.Sprintf("[MZH] setupResourceController TIME: %v", time.Since(start)))
-	// Start the VM
 	start = time.Now()
-	if err = s.startVM(ctx, prestartHookFunc); err != nil {
+	// Create Containers
+	err = s.createContainers(ctx)
+	elapsed = recordPhase(profile, progressFunc, PhaseCreateContainers, start, err)
+	katatrace.AddTags(span, string(PhaseCreateContainers)+"DurationMs", elapsed.Milliseconds())
+	if err != nil {
 		return nil, err
 	}
-	virtLog.Info(fmt.Sprintf("[MZH] startVM TIME: %v", time.Since(start)))
+	return s, nil
+}
+
+// RestoreSandbox is the virtcontainers sandbox restore entry point. Instead
+// of running CreateSandbox's normal creation flow, it reconstructs a sandbox
+// from a snapshot previously written by that sandbox's Sandbox.Snapshot.
+func RestoreSandbox(ctx context.Context, sandboxConfig SandboxConfig, snapshotPath string, factory Factory) (VCSandbox, error) {
+	span, ctx := katatrace.Trace(ctx, virtLog, "RestoreSandbox", apiTracingTags)
+	defer span.End()
+
+	s, err := restoreSandboxFromSnapshot(ctx, sandboxConfig, snapshotPath, factory)
+	return s, err
+}
+
+func restoreSandboxFromSnapshot(ctx context.Context, sandboxConfig SandboxConfig, snapshotPath string, factory Factory) (_ *Sandbox, err error) {
+	span, ctx := katatrace.Trace(ctx, virtLog, "restoreSandboxFromSnapshot", apiTracingTags)
+	defer span.End()
+
+	snapshot, err := loadSandboxSnapshot(snapshotPath)
+	if err != nil {
+		return nil, err
+	}
+
+	// Reconstruct the sandbox.
+	s, err := createSandboxFromSnapshot(ctx, sandboxConfig, snapshot)
+	if err != nil {
+		return nil, err
+	}
+	// Cleanup sandbox resources in case of any failure, mirroring
+	// createSandboxFromConfig's rollback defers.
+	defer func() {
+		if err != nil {
+			s.Delete(ctx)
+		}
+	}()
+
+	// network rollback
+	defer func() {
+		if err != nil {
+			virtLog.Info("Removing network after failure in restoreSandboxFromSnapshot")
+			s.removeNetwork(ctx)
+		}
+	}()
+	if err = s.restoreNetwork(ctx, snapshot); err != nil {
+		return nil, err
+	}
+
 	// rollback to stop VM if error occurs
 	defer func() {
 		if err != nil {
 			s.stopVM(ctx)
 		}
 	}()
-	start = time.Now()
-	s.postCreatedNetwork(ctx)
-	virtLog.Info(fmt.Sprintf("[MZH] postCreatedNetwork TIME: %v", time.Since(start)))
-	start = time.Now()
-	if err = s.getAndStoreGuestDetails(ctx); err != nil {
+	if err = s.restoreVM(ctx, snapshot, factory); err != nil {
 		return nil, err
 	}
-	virtLog.Info(fmt.Sprintf("[MZH] getAndStoreGuestDetails TIME: %v", time.Since(start)))
-	start = time.Now()
-	// Create Containers
-	if err = s.createContainers(ctx); err != nil {
+
+	// Reconcile container state instead of re-running createContainers.
+	if err = s.reconcileContainers(ctx, snapshot); err != nil {
 		return nil, err
 	}
-	virtLog.Info(fmt.Sprintf("[MZH] createContainers TIME: %v", time.Since(start)))
+
 	return s, nil
 }
 
+// AddSwap hotplugs a swap file of sizeMB megabytes into the guest VM backing
+// the sandbox identified by id, enabling the guest kernel to use it as
+// additional virtual memory. Only hypervisors that support block device
+// hotplug of swap devices (currently QEMU) honour this call.
+func AddSwap(ctx context.Context, id string, sizeMB int64) error {
+	span, ctx := katatrace.Trace(ctx, virtLog, "AddSwap", apiTracingTags)
+	defer span.End()
+
+	if id == "" {
+		return vcTypes.ErrNeedSandboxID
+	}
+
+	unlock, err := rwLockSandbox(id)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	s, err := fetchSandbox(ctx, id)
+	if err != nil {
+		return err
+	}
+	defer s.Release(ctx)
+
+	return s.AddSwap(ctx, sizeMB)
+}
+
+// RemoveSwap unplugs and removes the swap file previously added to the
+// sandbox identified by id via AddSwap.
+func RemoveSwap(ctx context.Context, id string) error {
+	span, ctx := katatrace.Trace(ctx, virtLog, "RemoveSwap", apiTracingTags)
+	defer span.End()
+
+	if id == "" {
+		return vcTypes.ErrNeedSandboxID
+	}
+
+	unlock, err := rwLockSandbox(id)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	s, err := fetchSandbox(ctx, id)
+	if err != nil {
+		return err
+	}
+	defer s.Release(ctx)
+
+	return s.RemoveSwap(ctx)
+}
+
 // CleanupContainer is used by shimv2 to stop and delete a container exclusively, once there is no container
 // in the sandbox left, do stop the sandbox and delete it. Those serial operations will be done exclusively by
 // locking the sandbox.
@@ -157,25 +287,68 @@ func CleanupContainer(ctx context.Context, sandboxID, containerID string, force
 	}
 	defer s.Release(ctx)
 
-	_, err = s.StopContainer(ctx, containerID, force)
-	if err != nil && !force {
-		return err
+	if !s.containerCleanupStepDone(containerID, containerCleanupStopped) {
+		start := time.Now()
+		_, err = s.StopContainer(ctx, containerID, force)
+		elapsed := recordPhase(nil, s.config.ProgressFunc, PhaseStopContainer, start, err)
+		katatrace.AddTags(span, string(PhaseStopContainer)+"DurationMs", elapsed.Milliseconds())
+		if err != nil && !force {
+			return s.markNotReady(ctx, fmt.Errorf("stopping container %s: %w", containerID, err))
+		}
+		if err := s.recordContainerCleanupStep(containerID, containerCleanupStopped); err != nil {
+			virtLog.WithError(err).WithField("container", containerID).Warn("failed to persist container cleanup progress")
+		}
 	}
 
-	_, err = s.DeleteContainer(ctx, containerID)
-	if err != nil && !force {
-		return err
+	if !s.containerCleanupStepDone(containerID, containerCleanupDeleted) {
+		start := time.Now()
+		_, err = s.DeleteContainer(ctx, containerID)
+		elapsed := recordPhase(nil, s.config.ProgressFunc, PhaseDeleteContainer, start, err)
+		katatrace.AddTags(span, string(PhaseDeleteContainer)+"DurationMs", elapsed.Milliseconds())
+		if err != nil && !force {
+			return s.markNotReady(ctx, fmt.Errorf("deleting container %s: %w", containerID, err))
+		}
+		if err := s.recordContainerCleanupStep(containerID, containerCleanupDeleted); err != nil {
+			virtLog.WithError(err).WithField("container", containerID).Warn("failed to persist container cleanup progress")
+		}
 	}
 
 	if len(s.GetAllContainers()) > 0 {
 		return nil
 	}
 
-	if err = s.Stop(ctx, force); err != nil && !force {
-		return err
+	return cleanupSandbox(ctx, s, force)
+}
+
+// cleanupSandbox stops and deletes a sandbox that has no containers left.
+// It is shared by CleanupContainer and ForceCleanupSandbox. It skips Stop
+// only when the sandbox is in StateStopped, so a sandbox already moved to
+// StateNotReady is stopped again on a retry. On a non-forced
+// failure persists the sandbox in StateNotReady rather than returning it
+// half torn down.
+func cleanupSandbox(ctx context.Context, s *Sandbox, force bool) error {
+	if s.state.State != vcTypes.StateStopped {
+		start := time.Now()
+		err := s.Stop(ctx, force)
+		recordPhase(nil, s.config.ProgressFunc, PhaseStopSandbox, start, err)
+		if err != nil && !force {
+			return s.markNotReady(ctx, fmt.Errorf("stopping sandbox: %w", err))
+		}
 	}
 
-	if err = s.Delete(ctx); err != nil {
+	if s.config.EnableSwap {
+		if err := s.RemoveSwap(ctx); err != nil && !force {
+			return s.markNotReady(ctx, fmt.Errorf("removing sandbox swap: %w", err))
+		}
+	}
+
+	start := time.Now()
+	err := s.Delete(ctx)
+	recordPhase(nil, s.config.ProgressFunc, PhaseDeleteSandbox, start, err)
+	if err != nil {
+		if !force {
+			return s.markNotReady(ctx, fmt.Errorf("deleting sandbox: %w", err))
+		}
 		return err
 	}
 
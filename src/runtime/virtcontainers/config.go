@@ -0,0 +1,44 @@
+// Copyright (c) 2016 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package virtcontainers
+
+import "time"
+
+// SandboxConfig is the configuration passed to CreateSandbox. It carries the
+// identity and per-hypervisor settings of the sandbox to create, along with
+// the opt-in knobs this package's entry points read directly.
+type SandboxConfig struct {
+	// ID is the sandbox's unique identifier.
+	ID string
+
+	// HypervisorType selects which VMM backs the sandbox.
+	HypervisorType HypervisorType
+
+	// Annotations carries pass-through CRI/OCI metadata.
+	Annotations map[string]string
+
+	// EnableSwap opts the sandbox into guest swap support. When set,
+	// AddSwap/RemoveSwap are allowed to hotplug a swap-tagged block
+	// device into the guest, and CleanupContainer/createSandboxFromConfig
+	// make sure any swap file is torn down along with the rest of the
+	// sandbox. Only hypervisors that support it (currently QEMU) honour
+	// this.
+	EnableSwap bool
+
+	// ProgressFunc, if non-nil, is called once each named creation or
+	// teardown phase finishes, with how long it took and its outcome (nil
+	// on success). It lets callers surface fine-grained progress (e.g. to
+	// a CRI streaming log) without scraping the Prometheus phase_duration
+	// histogram.
+	ProgressFunc func(phase string, elapsed time.Duration, err error)
+
+	// ParallelStartup opts into running createNetwork, setupResourceController
+	// and startVM concurrently instead of sequentially, via
+	// runNetworkCgroupsAndVM. See that function's doc comment for why the
+	// three phases are safe to run concurrently (each only writes its own,
+	// disjoint Sandbox fields until all three have returned).
+	ParallelStartup bool
+}
@@ -0,0 +1,59 @@
+// Copyright (c) 2016 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package virtcontainers
+
+import (
+	"context"
+	"fmt"
+	"testing"
+)
+
+// benchmarkCreateSandbox measures cold-start latency of CreateSandbox for
+// hypervisorType with and without ParallelStartup. It needs a real
+// hypervisor binary (qemu-system-*, cloud-hypervisor, ...) on PATH to boot
+// an actual guest, so on a machine without one it reports that rather than
+// failing the run: b.Skipf, not b.Fatalf, since "no hypervisor available" is
+// an environment fact, not a benchmark failure. Run with:
+//
+//	go test -run '^$' -bench BenchmarkCreateSandbox ./virtcontainers/...
+func benchmarkCreateSandbox(b *testing.B, hypervisorType HypervisorType, parallelStartup bool) {
+	ctx := context.Background()
+
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		config := SandboxConfig{
+			ID:              fmt.Sprintf("bench-%s-%d", hypervisorType, i),
+			HypervisorType:  hypervisorType,
+			ParallelStartup: parallelStartup,
+			Annotations:     map[string]string{},
+		}
+		b.StartTimer()
+
+		s, err := CreateSandbox(ctx, config, nil, nil)
+		if err != nil {
+			b.Skipf("CreateSandbox failed, no %s hypervisor available in this environment: %v", hypervisorType, err)
+		}
+		b.StopTimer()
+		s.Delete(ctx)
+		b.StartTimer()
+	}
+}
+
+func BenchmarkCreateSandboxQemuSequential(b *testing.B) {
+	benchmarkCreateSandbox(b, QemuHypervisor, false)
+}
+
+func BenchmarkCreateSandboxQemuParallel(b *testing.B) {
+	benchmarkCreateSandbox(b, QemuHypervisor, true)
+}
+
+func BenchmarkCreateSandboxClhSequential(b *testing.B) {
+	benchmarkCreateSandbox(b, ClhHypervisor, false)
+}
+
+func BenchmarkCreateSandboxClhParallel(b *testing.B) {
+	benchmarkCreateSandbox(b, ClhHypervisor, true)
+}
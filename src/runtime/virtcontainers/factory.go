@@ -0,0 +1,22 @@
+// Copyright (c) 2016 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package virtcontainers
+
+import "context"
+
+// Factory supplies pre-built or pre-restored VM instances so sandbox
+// creation and restore don't have to pay full boot/restore latency on the
+// hot path. Only the lookup restoreVM needs is defined here; the rest of
+// the factory (the template pool itself, its base/diff VM cache, eviction,
+// ...) lives alongside the rest of sandbox creation.
+type Factory interface {
+	// GetRestoredVM returns a hypervisor already restored from the
+	// snapshot file at hypervisorFile, if the factory has one warmed in
+	// its pool for hypervisorType, so restoreVM can skip calling
+	// hypervisor.Restore itself. ok is false if no such template is
+	// available and the caller should fall back to a direct Restore call.
+	GetRestoredVM(ctx context.Context, hypervisorType HypervisorType, hypervisorFile string) (hv hypervisor, ok bool, err error)
+}
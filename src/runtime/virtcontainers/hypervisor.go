@@ -0,0 +1,61 @@
+// Copyright (c) 2016 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package virtcontainers
+
+import (
+	"context"
+
+	deviceConfig "github.com/kata-containers/kata-containers/src/runtime/pkg/device/config"
+	"github.com/sirupsen/logrus"
+)
+
+// HypervisorType identifies which VMM backs a sandbox.
+type HypervisorType string
+
+const (
+	// QemuHypervisor is the QEMU VMM backend.
+	QemuHypervisor HypervisorType = "qemu"
+
+	// ClhHypervisor is the Cloud Hypervisor VMM backend.
+	ClhHypervisor HypervisorType = "clh"
+
+	// FirecrackerHypervisor is the Firecracker VMM backend.
+	FirecrackerHypervisor HypervisorType = "firecracker"
+
+	// AcrnHypervisor is the ACRN VMM backend.
+	AcrnHypervisor HypervisorType = "acrn"
+)
+
+// hypervisor is the subset of the VMM driver surface this package's
+// swap and snapshot/restore entry points call directly. The bulk of the
+// interface (boot, device hotplug beyond block devices, vCPU/memory
+// management, ...) lives alongside the rest of sandbox creation.
+type hypervisor interface {
+	// HotplugAddDevice attaches devInfo of the given devType to the
+	// running guest.
+	HotplugAddDevice(ctx context.Context, devInfo interface{}, devType deviceConfig.DeviceType) error
+
+	// HotplugRemoveDevice detaches devInfo of the given devType from the
+	// running guest.
+	HotplugRemoveDevice(ctx context.Context, devInfo interface{}, devType deviceConfig.DeviceType) error
+
+	// Snapshot writes the VMM's own memory and device state to path, with
+	// the guest already quiesced by the caller. The format is whatever the
+	// concrete backend's own live-migration/save support produces (e.g.
+	// QEMU's migrate-to-file).
+	Snapshot(ctx context.Context, path string) error
+
+	// Restore boots the guest from the memory/device state previously
+	// written to path by Snapshot, instead of a fresh boot.
+	Restore(ctx context.Context, path string) error
+}
+
+// SetHypervisorLogger sets the logger used by hypervisor implementations.
+func SetHypervisorLogger(logger *logrus.Entry) {
+	hypervisorLog = logger
+}
+
+var hypervisorLog = logrus.WithField("source", "hypervisor")
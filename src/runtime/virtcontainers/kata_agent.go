@@ -0,0 +1,123 @@
+// Copyright (c) 2016 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package virtcontainers
+
+import (
+	"context"
+	"fmt"
+
+	deviceConfig "github.com/kata-containers/kata-containers/src/runtime/pkg/device/config"
+)
+
+// agentGRPCClient is the transport kataAgent uses to reach the kata-agent
+// gRPC service running inside the guest. It is its own interface so this
+// file doesn't have to carry the connection setup/teardown that lives
+// alongside the rest of the agent client.
+type agentGRPCClient interface {
+	// ExecProcess runs name with args inside the guest and waits for it
+	// to exit, returning an error if it exits non-zero.
+	ExecProcess(ctx context.Context, name string, args []string) error
+
+	// Pause freezes every running container's processes inside the guest
+	// (the agent's equivalent of a cgroup freezer) ahead of a hypervisor
+	// snapshot.
+	Pause(ctx context.Context) error
+
+	// Reconnect re-dials the guest and replaces whatever RPC stream this
+	// client was using, after the guest was restored from a snapshot under
+	// a new connection.
+	Reconnect(ctx context.Context) error
+
+	// ContainerStatus returns container id's live state as the guest agent
+	// currently sees it (e.g. "running", "stopped").
+	ContainerStatus(ctx context.Context, id string) (string, error)
+}
+
+// kataAgent talks to the kata-agent running inside the guest over its gRPC
+// channel. Only the methods this package's swap and snapshot/restore entry
+// points need are defined here; the rest of the client lives alongside
+// sandbox creation.
+type kataAgent struct {
+	client agentGRPCClient
+}
+
+// addSwap runs mkswap/swapon inside the guest against the block device
+// identified by drive.
+func (k *kataAgent) addSwap(ctx context.Context, drive *deviceConfig.BlockDrive) error {
+	if err := k.guestExec(ctx, "mkswap", drive.File); err != nil {
+		return fmt.Errorf("mkswap failed for %s: %w", drive.File, err)
+	}
+
+	if err := k.guestExec(ctx, "swapon", drive.File); err != nil {
+		return fmt.Errorf("swapon failed for %s: %w", drive.File, err)
+	}
+
+	return nil
+}
+
+// removeSwap runs swapoff inside the guest against the block device
+// identified by drive.
+func (k *kataAgent) removeSwap(ctx context.Context, drive *deviceConfig.BlockDrive) error {
+	if err := k.guestExec(ctx, "swapoff", drive.File); err != nil {
+		return fmt.Errorf("swapoff failed for %s: %w", drive.File, err)
+	}
+
+	return nil
+}
+
+// pause asks the agent to freeze every running container's processes ahead
+// of a hypervisor snapshot.
+func (k *kataAgent) pause(ctx context.Context) error {
+	if k.client == nil {
+		return fmt.Errorf("pause: %w", ErrAgentUnreachable)
+	}
+
+	if err := k.client.Pause(ctx); err != nil {
+		return fmt.Errorf("failed to pause guest containers: %w", err)
+	}
+
+	return nil
+}
+
+// reconnect re-dials the guest agent after a hypervisor restore, so the
+// restored sandbox talks to the same agent process its snapshot quiesced.
+func (k *kataAgent) reconnect(ctx context.Context) error {
+	if k.client == nil {
+		return fmt.Errorf("reconnect: %w", ErrAgentUnreachable)
+	}
+
+	if err := k.client.Reconnect(ctx); err != nil {
+		return fmt.Errorf("failed to reconnect to guest agent: %w", err)
+	}
+
+	return nil
+}
+
+// containerStatus asks the agent for container id's live state, used by
+// reconcileContainers to confirm a restored snapshot's recorded state
+// against reality.
+func (k *kataAgent) containerStatus(ctx context.Context, id string) (string, error) {
+	if k.client == nil {
+		return "", fmt.Errorf("containerStatus: %w", ErrAgentUnreachable)
+	}
+
+	status, err := k.client.ContainerStatus(ctx, id)
+	if err != nil {
+		return "", fmt.Errorf("failed to query agent for container %s status: %w", id, err)
+	}
+
+	return status, nil
+}
+
+// guestExec runs name with args inside the guest via the agent's ExecProcess
+// gRPC call and waits for it to exit.
+func (k *kataAgent) guestExec(ctx context.Context, name string, args ...string) error {
+	if k.client == nil {
+		return fmt.Errorf("%s: %w", name, ErrAgentUnreachable)
+	}
+
+	return k.client.ExecProcess(ctx, name, args)
+}
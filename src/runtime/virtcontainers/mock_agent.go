@@ -0,0 +1,38 @@
+// Copyright (c) 2016 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package virtcontainers
+
+import (
+	"context"
+
+	deviceConfig "github.com/kata-containers/kata-containers/src/runtime/pkg/device/config"
+)
+
+// mockAgent is a no-op agent implementation, so callers that stub out
+// s.agent can exercise the swap entry points without a real guest VM.
+type mockAgent struct{}
+
+func (m *mockAgent) addSwap(ctx context.Context, drive *deviceConfig.BlockDrive) error {
+	return nil
+}
+
+func (m *mockAgent) removeSwap(ctx context.Context, drive *deviceConfig.BlockDrive) error {
+	return nil
+}
+
+func (m *mockAgent) pause(ctx context.Context) error {
+	return nil
+}
+
+func (m *mockAgent) reconnect(ctx context.Context) error {
+	return nil
+}
+
+// containerStatus has no guest to ask, so it reports "" (no opinion):
+// reconcileContainers keeps whatever state the snapshot already recorded.
+func (m *mockAgent) containerStatus(ctx context.Context, id string) (string, error) {
+	return "", nil
+}
@@ -0,0 +1,189 @@
+// Copyright (c) 2016 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package virtcontainers
+
+import (
+	"fmt"
+	"net"
+	"path/filepath"
+	"runtime"
+
+	"github.com/vishvananda/netlink"
+	"github.com/vishvananda/netns"
+
+	persistapi "github.com/kata-containers/kata-containers/src/runtime/virtcontainers/persist/api"
+)
+
+// collectNetworkInfo records the name, MAC, MTU and addresses of every
+// non-loopback interface in the network namespace at netNSPath, so a later
+// restore can reapply the same identity instead of allocating a fresh one.
+// An empty netNSPath (no namespace recorded yet) returns a zero NetworkInfo.
+func collectNetworkInfo(netNSPath string) (persistapi.NetworkInfo, error) {
+	if netNSPath == "" {
+		return persistapi.NetworkInfo{}, nil
+	}
+
+	info := persistapi.NetworkInfo{NetNSPath: netNSPath}
+
+	err := runInNetNS(netNSPath, func() error {
+		links, err := netlink.LinkList()
+		if err != nil {
+			return fmt.Errorf("failed to list links in %s: %w", netNSPath, err)
+		}
+
+		for _, link := range links {
+			attrs := link.Attrs()
+			if attrs.Name == "lo" {
+				continue
+			}
+
+			addrs, err := netlink.AddrList(link, netlink.FAMILY_ALL)
+			if err != nil {
+				return fmt.Errorf("failed to list addresses on %s: %w", attrs.Name, err)
+			}
+
+			addrStrs := make([]string, 0, len(addrs))
+			for _, addr := range addrs {
+				addrStrs = append(addrStrs, addr.IPNet.String())
+			}
+
+			info.Interfaces = append(info.Interfaces, persistapi.NetworkInterface{
+				Name:   attrs.Name,
+				HwAddr: attrs.HardwareAddr.String(),
+				MTU:    attrs.MTU,
+				Addrs:  addrStrs,
+			})
+		}
+
+		return nil
+	})
+
+	return info, err
+}
+
+// applyNetworkInfo rebuilds the sandbox's network namespace and taps to
+// match what was recorded in info, then reapplies their MACs, MTUs and
+// addresses. The namespace is recreated (see runInRecreatedNetNS for where
+// it is bind-mounted) if it no longer exists, and each recorded interface that isn't
+// already present is recreated as a tap device of the same name, since that
+// is what the hypervisor expects to attach a restored vNIC to.
+func applyNetworkInfo(info persistapi.NetworkInfo) error {
+	if info.NetNSPath == "" {
+		return nil
+	}
+
+	return runInRecreatedNetNS(info.NetNSPath, func() error {
+		for _, iface := range info.Interfaces {
+			link, err := netlink.LinkByName(iface.Name)
+			if err != nil {
+				tap := &netlink.Tuntap{
+					LinkAttrs: netlink.LinkAttrs{Name: iface.Name},
+					Mode:      netlink.TUNTAP_MODE_TAP,
+				}
+				if err := netlink.LinkAdd(tap); err != nil {
+					return fmt.Errorf("failed to recreate tap device %s: %w", iface.Name, err)
+				}
+				link = tap
+			}
+
+			if iface.HwAddr != "" {
+				hwAddr, err := net.ParseMAC(iface.HwAddr)
+				if err != nil {
+					return fmt.Errorf("failed to parse recorded MAC %q for %s: %w", iface.HwAddr, iface.Name, err)
+				}
+				if err := netlink.LinkSetHardwareAddr(link, hwAddr); err != nil {
+					return fmt.Errorf("failed to restore MAC on %s: %w", iface.Name, err)
+				}
+			}
+
+			if iface.MTU > 0 {
+				if err := netlink.LinkSetMTU(link, iface.MTU); err != nil {
+					return fmt.Errorf("failed to restore MTU on %s: %w", iface.Name, err)
+				}
+			}
+
+			for _, a := range iface.Addrs {
+				addr, err := netlink.ParseAddr(a)
+				if err != nil {
+					return fmt.Errorf("failed to parse recorded address %q for %s: %w", a, iface.Name, err)
+				}
+				if err := netlink.AddrAdd(link, addr); err != nil {
+					return fmt.Errorf("failed to restore address %s on %s: %w", a, iface.Name, err)
+				}
+			}
+
+			if err := netlink.LinkSetUp(link); err != nil {
+				return fmt.Errorf("failed to bring up restored interface %s: %w", iface.Name, err)
+			}
+		}
+
+		return nil
+	})
+}
+
+// runInNetNS runs fn with the calling goroutine's OS thread switched into
+// the network namespace at path, restoring the original namespace
+// afterwards. The OS thread is locked for the duration since namespaces are
+// per-thread, not per-goroutine.
+func runInNetNS(path string, fn func() error) error {
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	origNS, err := netns.Get()
+	if err != nil {
+		return fmt.Errorf("failed to get current network namespace: %w", err)
+	}
+	defer origNS.Close()
+
+	targetNS, err := netns.GetFromPath(path)
+	if err != nil {
+		return fmt.Errorf("failed to open network namespace %s: %w", path, err)
+	}
+	defer targetNS.Close()
+
+	if err := netns.Set(targetNS); err != nil {
+		return fmt.Errorf("failed to enter network namespace %s: %w", path, err)
+	}
+	defer netns.Set(origNS)
+
+	return fn()
+}
+
+// runInRecreatedNetNS is runInNetNS, except that if the network namespace at
+// path no longer exists (the sandbox's host-side state was torn down, e.g.
+// by a runtime/host restart between Snapshot and RestoreSandbox) it is
+// recreated first, so restore doesn't depend on anything else having
+// recreated it already. The new namespace is named after path's base name
+// and bind-mounted by netns.NewNamed in its own netns directory
+// (/run/netns), which is path itself only when path lies in that directory.
+// netns.NewNamed both creates
+// the namespace and switches the calling (locked) OS thread into it.
+func runInRecreatedNetNS(path string, fn func() error) error {
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	origNS, err := netns.Get()
+	if err != nil {
+		return fmt.Errorf("failed to get current network namespace: %w", err)
+	}
+	defer origNS.Close()
+
+	targetNS, err := netns.GetFromPath(path)
+	if err != nil {
+		targetNS, err = netns.NewNamed(filepath.Base(path))
+		if err != nil {
+			return fmt.Errorf("failed to recreate network namespace %s: %w", path, err)
+		}
+	}
+	defer targetNS.Close()
+
+	if err := netns.Set(targetNS); err != nil {
+		return fmt.Errorf("failed to enter network namespace %s: %w", path, err)
+	}
+	defer netns.Set(origNS)
+
+	return fn()
+}
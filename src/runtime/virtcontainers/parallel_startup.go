@@ -0,0 +1,85 @@
+// Copyright (c) 2016 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package virtcontainers
+
+import (
+	"context"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// phaseResult carries the outcome of one createSandboxFromConfig phase run
+// inside runNetworkCgroupsAndVM, so the caller can still feed it to
+// recordPhase and tag the creation span exactly like the sequential path
+// does.
+type phaseResult struct {
+	phase   creationPhase
+	elapsed time.Duration
+	err     error
+}
+
+// runNetworkCgroupsAndVM creates the sandbox network, sets up the host
+// resource controller, and starts the VM. None of the three depend on one
+// another, so when parallel is true they run concurrently via an errgroup;
+// postCreatedNetwork, which does need both the network and the VM, is left
+// to the caller to run afterwards. When parallel is false they run in the
+// same sequential order createSandboxFromConfig always used.
+//
+// In the parallel case, a failure in any one phase cancels the context
+// passed to the other two and runNetworkCgroupsAndVM still waits for them to
+// return before reporting the error, so the existing rollback defers in
+// createSandboxFromConfig (removeNetwork, stopVM, Delete) fire against
+// consistent state regardless of which phase failed.
+//
+// Running the three concurrently is only safe because each touches a
+// disjoint part of the Sandbox: createNetwork reads/writes s.networkNSPath and
+// the network-related fields reached through s.removeNetwork/postCreatedNetwork;
+// setupResourceController reads/writes only the host cgroup/resource
+// controller state under s.config and its own controller handle; and startVM
+// owns s.hypervisor's boot sequence and the VM-side fields stopVM tears down.
+// None of the three reads a field another one writes, so there is no shared
+// mutable state to race on. If a future phase needs to read another phase's
+// output (the way postCreatedNetwork needs both the network and the VM), it
+// must not be added to this set and should instead run after
+// runNetworkCgroupsAndVM returns, like postCreatedNetwork already does.
+func runNetworkCgroupsAndVM(ctx context.Context, s *Sandbox, prestartHookFunc func(context.Context) error, parallel bool) ([]phaseResult, error) {
+	steps := []struct {
+		phase creationPhase
+		run   func(context.Context) error
+	}{
+		{PhaseCreateNetwork, func(ctx context.Context) error { return s.createNetwork(ctx) }},
+		{PhaseSetupResourceController, func(context.Context) error { return s.setupResourceController() }},
+		{PhaseStartVM, func(ctx context.Context) error { return s.startVM(ctx, prestartHookFunc) }},
+	}
+
+	results := make([]phaseResult, len(steps))
+
+	if !parallel {
+		for i, step := range steps {
+			start := time.Now()
+			err := step.run(ctx)
+			results[i] = phaseResult{step.phase, time.Since(start), err}
+			if err != nil {
+				return results, err
+			}
+		}
+		return results, nil
+	}
+
+	g, gctx := errgroup.WithContext(ctx)
+	for i, step := range steps {
+		i, step := i, step
+		g.Go(func() error {
+			start := time.Now()
+			err := step.run(gctx)
+			results[i] = phaseResult{step.phase, time.Since(start), err}
+			return err
+		})
+	}
+
+	return results, g.Wait()
+}
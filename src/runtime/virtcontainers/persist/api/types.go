@@ -0,0 +1,44 @@
+// Copyright (c) 2016 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+// Package api defines the on-disk shapes Sandbox.Snapshot writes and
+// RestoreSandbox reads back. It is kept free of the virtcontainers package's
+// own types so the persisted format doesn't implicitly change every time an
+// unrelated virtcontainers type does.
+package api
+
+// NetworkInterface is one guest-facing interface's identity, recorded so a
+// restore can reapply the same MAC/IP/MTU rather than allocating fresh ones.
+type NetworkInterface struct {
+	Name   string   `json:"name"`
+	HwAddr string   `json:"hwAddr"`
+	MTU    int      `json:"mtu"`
+	Addrs  []string `json:"addrs"`
+}
+
+// NetworkInfo is the part of a sandbox's network state that needs to be
+// reapplied verbatim on restore.
+type NetworkInfo struct {
+	NetNSPath  string             `json:"netNSPath"`
+	Interfaces []NetworkInterface `json:"interfaces"`
+}
+
+// SandboxState is the subset of a Sandbox's state that Snapshot persists and
+// createSandboxFromPersistState uses to reconstruct the Sandbox struct
+// without re-running container creation.
+type SandboxState struct {
+	ID             string      `json:"id"`
+	HypervisorType string      `json:"hypervisorType"`
+	State          string      `json:"state"`
+	LastError      string      `json:"lastError,omitempty"`
+	Network        NetworkInfo `json:"network"`
+}
+
+// ContainerState is the subset of a container's state Snapshot persists for
+// reconcileContainers to reapply after restore.
+type ContainerState struct {
+	ID    string `json:"id"`
+	State string `json:"state"`
+}
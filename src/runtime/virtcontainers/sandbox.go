@@ -0,0 +1,212 @@
+// Copyright (c) 2016 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package virtcontainers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	persistapi "github.com/kata-containers/kata-containers/src/runtime/virtcontainers/persist/api"
+	vcTypes "github.com/kata-containers/kata-containers/src/runtime/virtcontainers/types"
+)
+
+// Sandbox is the fields of the sandbox struct this package's swap and
+// snapshot/restore code reads and writes directly. The rest of the sandbox
+// (containers, resource controller handles, network namespace plumbing, ...)
+// lives alongside the rest of sandbox creation.
+type Sandbox struct {
+	id         string
+	config     SandboxConfig
+	state      vcTypes.SandboxState
+	hypervisor hypervisor
+	agent      agent
+
+	// networkNSPath is the sandbox's network namespace, recorded so Snapshot
+	// can capture its interfaces and restoreNetwork can reapply them into
+	// the same namespace after a restore.
+	networkNSPath string
+
+	// containerStates tracks each container's last-known state, either as
+	// restored from a snapshot (reconcileContainerState) or as progress
+	// markers recorded by CleanupContainer (recordContainerCleanupStep), so
+	// a retried cleanup call can tell which per-container steps already
+	// completed without re-running them against a container that may no
+	// longer exist to retry against.
+	containerStates map[string]persistapi.ContainerState
+}
+
+// containerCleanupStopped and containerCleanupDeleted are the progress
+// markers recordContainerCleanupStep writes to containerStates, ordered so
+// containerCleanupStepDone can tell "done" from "not yet done" from "done
+// and past this step".
+const (
+	containerCleanupStopped = "cleanup-stopped"
+	containerCleanupDeleted = "cleanup-deleted"
+)
+
+// containerCleanupStepOrder ranks the cleanup progress markers so
+// containerCleanupStepDone can compare "how far did this container get"
+// against "how far does it need to be" with a plain integer comparison.
+var containerCleanupStepOrder = map[string]int{
+	"":                      0,
+	containerCleanupStopped: 1,
+	containerCleanupDeleted: 2,
+}
+
+// containerCleanupStepDone reports whether containerID has already reached
+// step (or a later one), as recorded by a previous recordContainerCleanupStep
+// call, so CleanupContainer can skip re-running it on a retry.
+func (s *Sandbox) containerCleanupStepDone(containerID, step string) bool {
+	cs, ok := s.containerStates[containerID]
+	if !ok {
+		return false
+	}
+
+	return containerCleanupStepOrder[cs.State] >= containerCleanupStepOrder[step]
+}
+
+// recordContainerCleanupStep persists that containerID has completed step,
+// so a retried CleanupContainer call doesn't repeat it.
+func (s *Sandbox) recordContainerCleanupStep(containerID, step string) error {
+	if s.containerStates == nil {
+		s.containerStates = make(map[string]persistapi.ContainerState)
+	}
+
+	s.containerStates[containerID] = persistapi.ContainerState{ID: containerID, State: step}
+
+	return s.Save()
+}
+
+// ID returns the sandbox's unique identifier.
+func (s *Sandbox) ID() string {
+	return s.id
+}
+
+// sandboxStateFile is the name of the persisted lifecycle-state document
+// written under the sandbox's own run directory.
+const sandboxStateFile = "state.json"
+
+// sandboxPersistedState is the shape Save writes to sandboxStateFile: the
+// sandbox's own lifecycle state plus each container's last-known state, so
+// both survive a runtime restart, not just an in-process retry.
+type sandboxPersistedState struct {
+	State           vcTypes.SandboxState                 `json:"state"`
+	ContainerStates map[string]persistapi.ContainerState `json:"containerStates,omitempty"`
+}
+
+// Save persists the sandbox's current lifecycle state (including LastError,
+// when set) and its containers' recorded states to its run directory, so a
+// transition like markNotReady's move to StateNotReady, or a
+// recordContainerCleanupStep progress marker, survives a runtime restart.
+func (s *Sandbox) Save() error {
+	dir := s.sandboxRootDir()
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return fmt.Errorf("failed to create sandbox run directory %s: %w", dir, err)
+	}
+
+	data, err := json.Marshal(sandboxPersistedState{
+		State:           s.state,
+		ContainerStates: s.containerStates,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal sandbox state: %w", err)
+	}
+
+	path := filepath.Join(dir, sandboxStateFile)
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to persist sandbox state to %s: %w", path, err)
+	}
+
+	return nil
+}
+
+// newAgent and newHypervisor construct the agent/hypervisor implementations
+// backing a sandbox. They are package variables, not plain functions, so
+// createSandboxFromPersistState can reuse exactly the seam the rest of
+// sandbox creation uses, and tests can swap in mockAgent or a fake
+// hypervisor without touching sandbox construction itself.
+var newAgent = func() agent {
+	return &kataAgent{}
+}
+
+var newHypervisor = func(hypervisorType HypervisorType) (hypervisor, error) {
+	return nil, fmt.Errorf("no hypervisor implementation registered for %q", hypervisorType)
+}
+
+// createSandboxFromPersistState reconstructs a Sandbox struct from a
+// snapshot's persisted state, the same way createSandbox builds one from a
+// SandboxConfig, but without touching the network, hypervisor or agent:
+// those are brought up afterwards by restoreNetwork and restoreVM.
+func createSandboxFromPersistState(ctx context.Context, sandboxConfig SandboxConfig, state persistapi.SandboxState) (*Sandbox, error) {
+	if state.ID == "" {
+		return nil, vcTypes.ErrNeedSandboxID
+	}
+
+	hv, err := newHypervisor(sandboxConfig.HypervisorType)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Sandbox{
+		id:         state.ID,
+		config:     sandboxConfig,
+		hypervisor: hv,
+		agent:      newAgent(),
+		state: vcTypes.SandboxState{
+			State:     vcTypes.StateString(state.State),
+			LastError: state.LastError,
+		},
+		networkNSPath:   state.Network.NetNSPath,
+		containerStates: make(map[string]persistapi.ContainerState),
+	}
+
+	if !s.state.State.Valid() {
+		return nil, fmt.Errorf("snapshot for sandbox %s has invalid state %q", state.ID, state.State)
+	}
+
+	return s, nil
+}
+
+// snapshotPersistState collects the sandbox's own state and its containers'
+// states into the shapes Sandbox.Snapshot persists.
+func (s *Sandbox) snapshotPersistState(ctx context.Context) (persistapi.SandboxState, map[string]persistapi.ContainerState, error) {
+	netInfo, err := collectNetworkInfo(s.networkNSPath)
+	if err != nil {
+		return persistapi.SandboxState{}, nil, fmt.Errorf("failed to collect network state for snapshot: %w", err)
+	}
+
+	sandboxState := persistapi.SandboxState{
+		ID:             s.id,
+		HypervisorType: string(s.config.HypervisorType),
+		State:          string(s.state.State),
+		LastError:      s.state.LastError,
+		Network:        netInfo,
+	}
+
+	containerStates := make(map[string]persistapi.ContainerState, len(s.containerStates))
+	for id, cs := range s.containerStates {
+		containerStates[id] = cs
+	}
+
+	return sandboxState, containerStates, nil
+}
+
+// reconcileContainerState records what the snapshot and the now-restored
+// agent report for container id, so callers that enumerate the sandbox's
+// containers after a restore see it without createContainers having run
+// again.
+func (s *Sandbox) reconcileContainerState(ctx context.Context, id string, cs persistapi.ContainerState) error {
+	if s.containerStates == nil {
+		s.containerStates = make(map[string]persistapi.ContainerState)
+	}
+
+	s.containerStates[id] = cs
+
+	return nil
+}
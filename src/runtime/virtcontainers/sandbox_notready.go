@@ -0,0 +1,80 @@
+// Copyright (c) 2016 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package virtcontainers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kata-containers/kata-containers/src/runtime/pkg/katautils/katatrace"
+	vcTypes "github.com/kata-containers/kata-containers/src/runtime/virtcontainers/types"
+)
+
+// ErrSandboxNotReady is returned by CleanupContainer when a teardown step
+// fails without force. The sandbox has been persisted in StateNotReady with
+// err recorded as the last error, and a later CleanupContainer or
+// ForceCleanupSandbox call will resume teardown from the last successful
+// step.
+type ErrSandboxNotReady struct {
+	SandboxID string
+	Cause     error
+}
+
+func (e *ErrSandboxNotReady) Error() string {
+	return fmt.Sprintf("sandbox %s retained in state %q for retry: %v", e.SandboxID, vcTypes.StateNotReady, e.Cause)
+}
+
+func (e *ErrSandboxNotReady) Unwrap() error {
+	return e.Cause
+}
+
+// markNotReady transitions the sandbox to StateNotReady, records cause as
+// the sandbox's last error, and persists the change so it survives a runtime
+// restart. It always returns an *ErrSandboxNotReady wrapping cause.
+func (s *Sandbox) markNotReady(ctx context.Context, cause error) error {
+	s.state.State = vcTypes.StateNotReady
+	s.state.LastError = cause.Error()
+
+	if err := s.Save(); err != nil {
+		virtLog.WithError(err).WithField("sandbox", s.ID()).Warn("failed to persist sandbox in StateNotReady")
+	}
+
+	return &ErrSandboxNotReady{SandboxID: s.ID(), Cause: cause}
+}
+
+// ForceCleanupSandbox fetches a sandbox left in StateNotReady by a previous
+// non-forced CleanupContainer failure and drives it through the sandbox
+// teardown steps with force set. The swap removal step is skipped if the
+// swap file is already gone; Stop is always run again, since StateNotReady
+// does not record whether the sandbox had already stopped. It is the repair path
+// shimv2 can use once CRI gives up retrying the regular CleanupContainer
+// call.
+func ForceCleanupSandbox(ctx context.Context, sandboxID string) error {
+	span, ctx := katatrace.Trace(ctx, virtLog, "ForceCleanupSandbox", apiTracingTags)
+	defer span.End()
+
+	if sandboxID == "" {
+		return vcTypes.ErrNeedSandboxID
+	}
+
+	unlock, err := rwLockSandbox(sandboxID)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	s, err := fetchSandbox(ctx, sandboxID)
+	if err != nil {
+		return err
+	}
+	defer s.Release(ctx)
+
+	if s.state.State != vcTypes.StateNotReady {
+		return fmt.Errorf("sandbox %s is not in state %q, refusing to force cleanup", sandboxID, vcTypes.StateNotReady)
+	}
+
+	return cleanupSandbox(ctx, s, true)
+}
@@ -0,0 +1,172 @@
+// Copyright (c) 2016 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package virtcontainers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/kata-containers/kata-containers/src/runtime/pkg/katautils/katatrace"
+	persistapi "github.com/kata-containers/kata-containers/src/runtime/virtcontainers/persist/api"
+)
+
+// sandboxSnapshot is the on-disk format written by Sandbox.Snapshot and read
+// back by RestoreSandbox. It pairs the hypervisor's own memory/device state
+// dump with the persistapi state blob the runtime needs to reconstruct the
+// Sandbox struct without re-running container creation.
+type sandboxSnapshot struct {
+	SandboxState   persistapi.SandboxState              `json:"sandboxState"`
+	ContainerState map[string]persistapi.ContainerState `json:"containerState"`
+	HypervisorFile string                               `json:"hypervisorFile"`
+}
+
+// Snapshot quiesces the sandbox's containers via the agent, asks the
+// hypervisor to write its memory and device state to a file next to path,
+// and writes a sandboxSnapshot describing both to path. A matching
+// RestoreSandbox call reconstructs the sandbox from exactly these two
+// artifacts.
+func (s *Sandbox) Snapshot(ctx context.Context, path string) error {
+	span, ctx := katatrace.Trace(ctx, virtLog, "Snapshot", apiTracingTags)
+	defer span.End()
+
+	if err := s.agent.pause(ctx); err != nil {
+		return fmt.Errorf("failed to quiesce containers before snapshot: %w", err)
+	}
+
+	hypervisorFile := path + ".hv"
+	if err := s.hypervisor.Snapshot(ctx, hypervisorFile); err != nil {
+		return fmt.Errorf("failed to snapshot hypervisor state: %w", err)
+	}
+
+	sandboxState, containerState, err := s.snapshotPersistState(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to collect sandbox persist state: %w", err)
+	}
+
+	snapshot := sandboxSnapshot{
+		SandboxState:   sandboxState,
+		ContainerState: containerState,
+		HypervisorFile: hypervisorFile,
+	}
+
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return fmt.Errorf("failed to marshal sandbox snapshot: %w", err)
+	}
+
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write sandbox snapshot %s: %w", path, err)
+	}
+
+	return nil
+}
+
+// loadSandboxSnapshot reads back a sandboxSnapshot previously written by
+// Sandbox.Snapshot.
+func loadSandboxSnapshot(path string) (*sandboxSnapshot, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read sandbox snapshot %s: %w", path, err)
+	}
+
+	var snapshot sandboxSnapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal sandbox snapshot %s: %w", path, err)
+	}
+
+	return &snapshot, nil
+}
+
+// createSandboxFromSnapshot reconstructs a Sandbox struct from a previously
+// recorded snapshot's persistapi state, the same way createSandbox builds
+// one from sandboxConfig, but without touching the network, the hypervisor
+// or the agent: those are brought up by restoreNetwork and restoreVM once
+// this returns. It takes no Factory: building the struct itself never
+// sources a VM, so there is nothing here for a template pool to shortcut.
+func createSandboxFromSnapshot(ctx context.Context, sandboxConfig SandboxConfig, snapshot *sandboxSnapshot) (*Sandbox, error) {
+	s, err := createSandboxFromPersistState(ctx, sandboxConfig, snapshot.SandboxState)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reconstruct sandbox from snapshot: %w", err)
+	}
+
+	return s, nil
+}
+
+// restoreNetwork reapplies the MACs, MTUs and addresses recorded in
+// snapshot onto the sandbox's network namespace, rather than allocating
+// fresh ones the way createNetwork does. If the namespace or any recorded
+// interface no longer exists, applyNetworkInfo recreates it (interfaces as
+// tap devices) before reapplying identity.
+func (s *Sandbox) restoreNetwork(ctx context.Context, snapshot *sandboxSnapshot) error {
+	if err := applyNetworkInfo(snapshot.SandboxState.Network); err != nil {
+		return err
+	}
+
+	s.networkNSPath = snapshot.SandboxState.Network.NetNSPath
+
+	return nil
+}
+
+// restoreVM launches the hypervisor against the memory/device state recorded
+// in snapshot (QEMU `-incoming`, Cloud Hypervisor/Firecracker restore
+// semantics, depending on s.config.HypervisorType) instead of booting a
+// fresh guest, then re-attaches the agent gRPC channel to it. When factory
+// is non-nil, it is asked first for a template already restored from
+// snapshot.HypervisorFile; only when it has none does restoreVM fall back to
+// restoring s.hypervisor directly.
+func (s *Sandbox) restoreVM(ctx context.Context, snapshot *sandboxSnapshot, factory Factory) error {
+	restoredFromFactory := false
+
+	if factory != nil {
+		hv, ok, err := factory.GetRestoredVM(ctx, s.config.HypervisorType, snapshot.HypervisorFile)
+		if err != nil {
+			return fmt.Errorf("failed to query factory for a pre-restored template: %w", err)
+		}
+		if ok {
+			s.hypervisor = hv
+			restoredFromFactory = true
+		}
+	}
+
+	if !restoredFromFactory {
+		if err := s.hypervisor.Restore(ctx, snapshot.HypervisorFile); err != nil {
+			return fmt.Errorf("failed to restore hypervisor state: %w", err)
+		}
+	}
+
+	if err := s.agent.reconnect(ctx); err != nil {
+		return fmt.Errorf("failed to reattach agent after restore: %w", err)
+	}
+
+	return nil
+}
+
+// reconcileContainers reconciles in-memory container state with what the
+// snapshot and the now-restored agent report, instead of re-running
+// createContainers against a guest that already has them running. For each
+// container it asks the agent for its live state and prefers that over the
+// snapshot's recorded state whenever the agent has an opinion, since the
+// guest is the source of truth once restoreVM has reattached to it.
+func (s *Sandbox) reconcileContainers(ctx context.Context, snapshot *sandboxSnapshot) error {
+	for id, cs := range snapshot.ContainerState {
+		liveState, err := s.agent.containerStatus(ctx, id)
+		if err != nil {
+			return fmt.Errorf("failed to query live state for container %s: %w", id, err)
+		}
+
+		if liveState != "" {
+			cs.State = liveState
+		}
+
+		if err := s.reconcileContainerState(ctx, id, cs); err != nil {
+			return fmt.Errorf("failed to reconcile container %s: %w", id, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,139 @@
+// Copyright (c) 2016 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package virtcontainers
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"context"
+
+	deviceConfig "github.com/kata-containers/kata-containers/src/runtime/pkg/device/config"
+	"github.com/kata-containers/kata-containers/src/runtime/pkg/katautils/katatrace"
+)
+
+const swapFileName = "swapfile"
+
+// sandboxesRootDir is where each sandbox gets a per-sandbox run directory
+// for host-side state like the swap file.
+const sandboxesRootDir = "/run/vc/sbs"
+
+// sandboxRootDir is the sandbox's own run directory under sandboxesRootDir,
+// used to hold host-side files (the swap file and Save's sandboxStateFile)
+// that belong to this sandbox specifically.
+func (s *Sandbox) sandboxRootDir() string {
+	return filepath.Join(sandboxesRootDir, s.ID())
+}
+
+// swapSupportedHypervisors lists the hypervisor backends that know how to
+// hotplug a swap-tagged block device into a running guest. Firecracker,
+// Cloud Hypervisor and ACRN are deliberately left out until they grow the
+// same support.
+var swapSupportedHypervisors = map[HypervisorType]bool{
+	QemuHypervisor: true,
+}
+
+// AddSwap allocates a sparse swap file under the sandbox's run directory,
+// hotplugs it into the guest as a block device tagged for swap, and asks the
+// agent to format and enable it inside the VM.
+func (s *Sandbox) AddSwap(ctx context.Context, sizeMB int64) error {
+	span, ctx := katatrace.Trace(ctx, virtLog, "AddSwap", apiTracingTags)
+	defer span.End()
+
+	if sizeMB <= 0 {
+		return fmt.Errorf("swap size must be positive, got %dMB", sizeMB)
+	}
+
+	if !s.config.EnableSwap {
+		return fmt.Errorf("sandbox %s was not created with swap enabled", s.ID())
+	}
+
+	if !swapSupportedHypervisors[s.config.HypervisorType] {
+		return fmt.Errorf("guest swap is not supported on hypervisor %q", s.config.HypervisorType)
+	}
+
+	if err := os.MkdirAll(s.sandboxRootDir(), 0700); err != nil {
+		return fmt.Errorf("failed to create sandbox run directory %s: %w", s.sandboxRootDir(), err)
+	}
+
+	swapFilePath := filepath.Join(s.sandboxRootDir(), swapFileName)
+	if err := createSparseSwapFile(swapFilePath, sizeMB); err != nil {
+		return err
+	}
+
+	drive := &deviceConfig.BlockDrive{
+		File: swapFilePath,
+		Swap: true,
+	}
+
+	if err := s.hypervisor.HotplugAddDevice(ctx, drive, deviceConfig.DeviceBlock); err != nil {
+		os.Remove(swapFilePath)
+		return fmt.Errorf("failed to hotplug swap device: %w", err)
+	}
+
+	if err := s.agent.addSwap(ctx, drive); err != nil {
+		s.hypervisor.HotplugRemoveDevice(ctx, drive, deviceConfig.DeviceBlock)
+		os.Remove(swapFilePath)
+		return fmt.Errorf("failed to enable swap in guest: %w", err)
+	}
+
+	return nil
+}
+
+// RemoveSwap disables the swap device inside the guest, unplugs it from the
+// VM, and removes the backing file from the host. It is safe to call more
+// than once for the same sandbox: if the swap file is already gone (a prior
+// call completed the host-side cleanup but the caller retried after losing
+// the response, or this is a resumed ForceCleanupSandbox/CleanupContainer
+// pass), RemoveSwap treats that as already-removed and returns nil instead
+// of failing again, so a sandbox stuck in StateNotReady can converge.
+func (s *Sandbox) RemoveSwap(ctx context.Context) error {
+	span, ctx := katatrace.Trace(ctx, virtLog, "RemoveSwap", apiTracingTags)
+	defer span.End()
+
+	swapFilePath := filepath.Join(s.sandboxRootDir(), swapFileName)
+
+	if _, err := os.Stat(swapFilePath); os.IsNotExist(err) {
+		return nil
+	}
+
+	drive := &deviceConfig.BlockDrive{
+		File: swapFilePath,
+		Swap: true,
+	}
+
+	if err := s.agent.removeSwap(ctx, drive); err != nil && !isAgentUnreachable(err) {
+		return fmt.Errorf("failed to disable swap in guest: %w", err)
+	}
+
+	if err := s.hypervisor.HotplugRemoveDevice(ctx, drive, deviceConfig.DeviceBlock); err != nil && !isAgentUnreachable(err) {
+		return fmt.Errorf("failed to unplug swap device: %w", err)
+	}
+
+	if err := os.Remove(swapFilePath); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove swap file %s: %w", swapFilePath, err)
+	}
+
+	return nil
+}
+
+// createSparseSwapFile creates (or truncates) a sparse file of sizeMB
+// megabytes at path, suitable for use as a guest swap device.
+func createSparseSwapFile(path string, sizeMB int64) error {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to create swap file %s: %w", path, err)
+	}
+	defer f.Close()
+
+	if err := f.Truncate(sizeMB * 1024 * 1024); err != nil {
+		os.Remove(path)
+		return fmt.Errorf("failed to allocate swap file %s: %w", path, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,68 @@
+// Copyright (c) 2016 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package types
+
+import "errors"
+
+// ErrNeedSandboxID is returned by API entry points that require a sandbox ID
+// when one was not supplied.
+var ErrNeedSandboxID = errors.New("ID cannot be empty")
+
+// ErrNeedContainerID is returned by API entry points that require a
+// container ID when one was not supplied.
+var ErrNeedContainerID = errors.New("ContainerID cannot be empty")
+
+// StateString is a sandbox or container's lifecycle state.
+type StateString string
+
+const (
+	// StateReady marks a sandbox/container that has been created but not
+	// started.
+	StateReady StateString = "ready"
+
+	// StateRunning marks a sandbox/container that is running.
+	StateRunning StateString = "running"
+
+	// StatePaused marks a sandbox/container that has been paused.
+	StatePaused StateString = "paused"
+
+	// StateStopped marks a sandbox/container that has been stopped.
+	StateStopped StateString = "stopped"
+
+	// StateNotReady marks a sandbox that started tearing down but hit a
+	// non-forced failure partway through: some of its resources (network
+	// namespace, cgroups, vhost/tap devices) may still be present on the
+	// host, but it can no longer run workloads. CleanupContainer persists
+	// a sandbox here instead of returning it half torn down, so a later
+	// CleanupContainer or ForceCleanupSandbox call can resume teardown.
+	StateNotReady StateString = "not-ready"
+)
+
+// validStates are the only values the persist store accepts for
+// SandboxState.State on reload.
+var validStates = map[StateString]bool{
+	StateReady:    true,
+	StateRunning:  true,
+	StatePaused:   true,
+	StateStopped:  true,
+	StateNotReady: true,
+}
+
+// Valid reports whether s is a state the persist store will accept.
+func (s StateString) Valid() bool {
+	return validStates[s]
+}
+
+// SandboxState is the sandbox's persisted lifecycle state.
+type SandboxState struct {
+	// State is the sandbox's current lifecycle state.
+	State StateString
+
+	// LastError records the error that moved the sandbox to
+	// StateNotReady, so operators and ForceCleanupSandbox callers can see
+	// why teardown stalled without having to correlate log timestamps.
+	LastError string
+}